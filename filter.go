@@ -0,0 +1,72 @@
+package filters
+
+// Filter mirrors the LoopBack query filter language: a where clause made
+// of one or more condition maps (implicitly ANDed together), a sort
+// order, pagination, a field projection, and relation includes.
+//
+// Fields selects which top-level document keys end up in the result,
+// LoopBack style: either every key maps to true, in which case only
+// those keys are kept, or every key maps to false, in which case only
+// those keys are dropped. Mixing true and false in the same map is
+// invalid. A nil or empty Fields keeps the document as-is. Dotted or
+// bracketed paths are not supported here, only plain top-level keys.
+type Filter struct {
+	Offset  int                      `json:"offset,omitempty"`
+	Limit   int                      `json:"limit,omitempty"`
+	Sort    []string                 `json:"order,omitempty"`
+	Where   []map[string]interface{} `json:"where,omitempty"`
+	Fields  map[string]bool          `json:"fields,omitempty"`
+	Include []IncludeSpec            `json:"include,omitempty"`
+	Group   *Group                   `json:"group,omitempty"`
+}
+
+// IncludeSpec requests a relation, previously registered on the
+// filterProcessor with RegisterRelation, to be fetched and merged into
+// the root document under the relation's name. Scope filters, sorts and
+// paginates the related documents the same way a top-level Filter would.
+type IncludeSpec struct {
+	Relation string
+	Scope    *Filter
+}
+
+// Group describes an AQL COLLECT/AGGREGATE: By lists the fields to group
+// on, and Aggregate maps a result variable name to a single-key
+// aggregation spec, e.g. {"sum": "amount"} or {"count": true}. Supported
+// aggregators are "sum", "avg", "min", "max" and "count".
+type Group struct {
+	By        []string                          `json:"by,omitempty"`
+	Aggregate map[string]map[string]interface{} `json:"aggregate,omitempty"`
+}
+
+// Processed holds the AQL fragments produced from a Filter by a
+// filterProcessor. Each field is meant to be spliced as-is into the
+// corresponding clause of the AQL query it was generated for.
+//
+// BindVars is nil unless the processor was built WithBindVars, in which
+// case it holds the values referenced by the @p0, @p1, ... placeholders
+// in Where and OffsetLimit, ready to pass to the driver alongside the
+// query string.
+//
+// Return is empty unless the Filter carried Fields or Include, in which
+// case it holds a projection expression meant to be spliced after RETURN
+// instead of the bare document variable.
+//
+// Lets holds one `LET <relation> = (...)` AQL statement per entry of
+// Include, each a subquery traversing the relation's edge collection.
+// They must be spliced right before the RETURN of the query Processed
+// was generated for, so that Return can reference them.
+//
+// Collect and Aggregate are both empty unless the Filter carried a
+// Group. Collect holds the `key = expr, ...` assignments meant to follow
+// COLLECT, and Aggregate the `name = FUNC(expr), ...` assignments meant
+// to follow AGGREGATE.
+type Processed struct {
+	OffsetLimit string
+	Sort        string
+	Where       string
+	BindVars    map[string]interface{}
+	Return      string
+	Lets        []string
+	Collect     string
+	Aggregate   string
+}
@@ -0,0 +1,889 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultVarName is used whenever a filterProcessor is created without an
+// explicit AQL variable name.
+const defaultVarName = "var"
+
+// identifierRegexp matches a single safe AQL identifier: letters, digits
+// and underscores, starting with a letter or underscore.
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// segmentRegexp matches a single safe path segment: an identifier
+// (letters, digits and underscores, starting with a letter or
+// underscore), optionally followed by an array index such as "[0]".
+var segmentRegexp = regexp.MustCompile(`^([a-zA-Z_][a-zA-Z0-9_]*)(\[[0-9]+\])?$`)
+
+// reservedWords blacklists AQL keywords that must never be accepted as
+// identifiers, even though they otherwise look like valid ones.
+var reservedWords = map[string]bool{
+	"insert":  true,
+	"update":  true,
+	"replace": true,
+	"remove":  true,
+	"upsert":  true,
+	"for":     true,
+	"return":  true,
+	"let":     true,
+	"collect": true,
+	"filter":  true,
+	"sort":    true,
+	"limit":   true,
+}
+
+// aggregatorFuncs maps the value-based Group aggregators to their AQL
+// aggregate function. "count" is handled separately since it takes no
+// field.
+var aggregatorFuncs = map[string]string{
+	"sum": "SUM",
+	"avg": "AVERAGE",
+	"min": "MIN",
+	"max": "MAX",
+}
+
+// comparisonOperators maps the condition operators to their AQL symbol.
+var comparisonOperators = map[string]string{
+	"eq":  "==",
+	"neq": "!=",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+// Direction is the edge traversal direction used when resolving a
+// registered relation.
+type Direction int
+
+const (
+	Outbound Direction = iota
+	Inbound
+	Any
+)
+
+// aql renders the direction as its AQL traversal keyword.
+func (d Direction) aql() string {
+	switch d {
+	case Inbound:
+		return "INBOUND"
+	case Any:
+		return "ANY"
+	default:
+		return "OUTBOUND"
+	}
+}
+
+// relationSpec describes how to reach a related collection through an
+// edge collection, as registered with RegisterRelation.
+type relationSpec struct {
+	edgeCollection string
+	direction      Direction
+	targetVar      string
+}
+
+// Option configures a filterProcessor at construction time.
+type Option func(*filterProcessor)
+
+// WithBindVars makes the processor emit AQL bind parameters (@p0, @p1, ...)
+// instead of inlining literals into the generated fragments. This is the
+// recommended mode: besides avoiding injection footguns in hand-rolled
+// Filters, it lets ArangoDB cache the query plan across calls that only
+// differ by bound values. The string-only literal mode remains the
+// default so existing callers keep working unchanged.
+func WithBindVars() Option {
+	return func(p *filterProcessor) {
+		p.bindVars = true
+	}
+}
+
+// filterProcessor turns a Filter into ready-to-splice AQL fragments, all
+// referencing the same AQL variable (typically the FOR loop variable of
+// the query the fragments are meant to complete).
+type filterProcessor struct {
+	varName   string
+	bindVars  bool
+	relations map[string]relationSpec
+}
+
+// newFilterProcessor returns a filterProcessor generating AQL fragments
+// against varName (e.g. "u" for `FOR u IN users`). An empty varName
+// defaults to "var".
+func newFilterProcessor(varName string, opts ...Option) filterProcessor {
+	if varName == "" {
+		varName = defaultVarName
+	}
+	p := filterProcessor{varName: varName}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
+}
+
+// RegisterRelation teaches the processor how to resolve an Include whose
+// Relation is name: targetVar traverses direction over edgeCollection,
+// starting from the processor's own varName.
+func (p *filterProcessor) RegisterRelation(name, edgeCollection string, direction Direction, targetVar string) {
+	if p.relations == nil {
+		p.relations = map[string]relationSpec{}
+	}
+	p.relations[name] = relationSpec{
+		edgeCollection: edgeCollection,
+		direction:      direction,
+		targetVar:      targetVar,
+	}
+}
+
+// Process turns f into its AQL representation. A nil Filter is valid and
+// yields an empty Processed. When the processor was built WithBindVars,
+// Processed.BindVars is populated and the Where/OffsetLimit fragments
+// reference it through @p0, @p1, ... placeholders instead of literals.
+func (p filterProcessor) Process(f *Filter) (*Processed, error) {
+	run := &processRun{filterProcessor: p}
+	if p.bindVars {
+		run.bindVars = map[string]interface{}{}
+	}
+
+	if f == nil {
+		return run.result("", "", "", "", nil, "", ""), nil
+	}
+
+	sort, err := run.processSort(f.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	where, err := run.processWhere(f.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := run.processFields(f.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	lets, mergeObj, err := run.processIncludes(f.Include)
+	if err != nil {
+		return nil, err
+	}
+	ret = mergeReturn(ret, run.varName, mergeObj)
+
+	collect, aggregate, err := run.processGroup(f.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	return run.result(run.processOffsetLimit(f.Offset, f.Limit), sort, where, ret, lets, collect, aggregate), nil
+}
+
+// mergeReturn folds a relation merge object, if any, into base. base is
+// either a projection expression from processFields or empty, in which
+// case it defaults to varName.
+func mergeReturn(base, varName, mergeObj string) string {
+	if mergeObj == "" {
+		return base
+	}
+	if base == "" {
+		base = varName
+	}
+	return fmt.Sprintf("MERGE(%s, %s)", base, mergeObj)
+}
+
+// processRun carries the mutable state of a single Process call: the bind
+// parameter map and counter, when bind vars are enabled, plus the AQL
+// variable currently in scope. Keeping this state off filterProcessor
+// itself lets a single processor be reused concurrently across
+// independent Process calls.
+type processRun struct {
+	filterProcessor
+	bindVars  map[string]interface{}
+	bindCount int
+}
+
+func (r *processRun) result(offsetLimit, sort, where, ret string, lets []string, collect, aggregate string) *Processed {
+	return &Processed{
+		OffsetLimit: offsetLimit,
+		Sort:        sort,
+		Where:       where,
+		BindVars:    r.bindVars,
+		Return:      ret,
+		Lets:        lets,
+		Collect:     collect,
+		Aggregate:   aggregate,
+	}
+}
+
+// bind registers value under a fresh @pN placeholder and returns it.
+func (r *processRun) bind(value interface{}) string {
+	key := fmt.Sprintf("p%d", r.bindCount)
+	r.bindCount++
+	r.bindVars[key] = value
+	return "@" + key
+}
+
+// processOffsetLimit renders the OFFSET, COUNT of an AQL LIMIT clause, as
+// literals or as bind parameters when the processor runs WithBindVars.
+func (r *processRun) processOffsetLimit(offset, limit int) string {
+	switch {
+	case offset > 0 && limit > 0:
+		return fmt.Sprintf("%s, %s", r.intLiteral(offset), r.intLiteral(limit))
+	case limit > 0:
+		return r.intLiteral(limit)
+	case offset > 0:
+		return r.intLiteral(offset)
+	default:
+		return ""
+	}
+}
+
+// intLiteral renders an int as an AQL literal, or as a bind parameter
+// when the processor runs WithBindVars.
+func (r *processRun) intLiteral(value int) string {
+	if r.bindVars != nil {
+		return r.bind(value)
+	}
+	return strconv.Itoa(value)
+}
+
+func (r *processRun) processSort(sort []string) (string, error) {
+	clauses := make([]string, 0, len(sort))
+	for _, s := range sort {
+		fields := strings.Fields(s)
+		if len(fields) == 0 || len(fields) > 2 {
+			return "", fmt.Errorf("arangofilters: invalid sort clause %q", s)
+		}
+
+		field, err := r.processIdentifier(fields[0])
+		if err != nil {
+			return "", err
+		}
+
+		direction := "ASC"
+		if len(fields) == 2 {
+			direction = strings.ToUpper(fields[1])
+			if direction != "ASC" && direction != "DESC" {
+				return "", fmt.Errorf("arangofilters: invalid sort direction %q", fields[1])
+			}
+		}
+
+		clauses = append(clauses, field+" "+direction)
+	}
+
+	return strings.Join(clauses, ", "), nil
+}
+
+// pathSegment is one dot-separated piece of a field path, e.g. "city" or
+// "tags[0]".
+type pathSegment struct {
+	name  string
+	index string // e.g. "[0]", or "" when the segment isn't indexed
+}
+
+func (s pathSegment) render() string {
+	return s.name + s.index
+}
+
+// parseSegments splits a field path such as "address.city" or "tags[0]"
+// into its dot-separated segments, validating each one against the AQL
+// identifier whitelist.
+func (r *processRun) parseSegments(identifier string) ([]pathSegment, error) {
+	parts := strings.Split(identifier, ".")
+	segments := make([]pathSegment, 0, len(parts))
+	for _, part := range parts {
+		m := segmentRegexp.FindStringSubmatch(part)
+		if m == nil {
+			return nil, fmt.Errorf("arangofilters: invalid identifier %q", identifier)
+		}
+		if reservedWords[strings.ToLower(m[1])] {
+			return nil, fmt.Errorf("arangofilters: %q is a reserved keyword", identifier)
+		}
+		segments = append(segments, pathSegment{name: m[1], index: m[2]})
+	}
+	return segments, nil
+}
+
+// processFields turns a Filter's Fields projection into a RETURN
+// expression. An all-true map keeps only the listed keys, rendered as
+// `{ key: var.key, ... }`; an all-false map drops them via AQL's UNSET.
+// Mixing true and false in the same map is rejected. An empty Fields
+// yields an empty expression (there's nothing to project).
+func (r *processRun) processFields(fields map[string]bool) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	include := fields[keys[0]]
+	for _, key := range keys {
+		if fields[key] != include {
+			return "", fmt.Errorf(`arangofilters: "fields" cannot mix included and excluded keys`)
+		}
+	}
+
+	if include {
+		entries := make([]string, 0, len(keys))
+		for _, key := range keys {
+			name, err := r.validateName(key)
+			if err != nil {
+				return "", err
+			}
+			entries = append(entries, name+": "+r.varName+"."+name)
+		}
+		return "{ " + strings.Join(entries, ", ") + " }", nil
+	}
+
+	excluded := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name, err := r.validateName(key)
+		if err != nil {
+			return "", err
+		}
+		excluded = append(excluded, `"`+name+`"`)
+	}
+	return fmt.Sprintf("UNSET(%s, %s)", r.varName, strings.Join(excluded, ", ")), nil
+}
+
+// processIncludes turns each IncludeSpec into a `LET <relation> = (...)`
+// subquery and returns them alongside the `{ relation: relation, ... }`
+// object the caller should MERGE into its own RETURN.
+func (r *processRun) processIncludes(includes []IncludeSpec) ([]string, string, error) {
+	if len(includes) == 0 {
+		return nil, "", nil
+	}
+
+	lets := make([]string, 0, len(includes))
+	merged := make([]string, 0, len(includes))
+	for _, include := range includes {
+		rel, ok := r.relations[include.Relation]
+		if !ok {
+			return nil, "", fmt.Errorf("arangofilters: unknown relation %q", include.Relation)
+		}
+
+		subquery, err := r.processRelation(rel, include.Scope)
+		if err != nil {
+			return nil, "", err
+		}
+
+		lets = append(lets, fmt.Sprintf("LET %s = (%s)", include.Relation, subquery))
+		merged = append(merged, fmt.Sprintf("%s: %s", include.Relation, include.Relation))
+	}
+
+	return lets, "{ " + strings.Join(merged, ", ") + " }", nil
+}
+
+// processRelation renders a single relation as an AQL traversal subquery,
+// processing scope's where/sort/limit/fields/include against the
+// relation's target variable. It reuses r's bind parameter state, so
+// nested bind vars keep counting up from the parent scope's @pN.
+func (r *processRun) processRelation(rel relationSpec, scope *Filter) (string, error) {
+	parentVar := r.varName
+
+	oldVar := r.varName
+	r.varName = rel.targetVar
+	defer func() { r.varName = oldVar }()
+
+	var sortClause, where, ret, offsetLimit string
+	var lets []string
+
+	if scope != nil {
+		var err error
+		if sortClause, err = r.processSort(scope.Sort); err != nil {
+			return "", err
+		}
+		if where, err = r.processWhere(scope.Where); err != nil {
+			return "", err
+		}
+		if ret, err = r.processFields(scope.Fields); err != nil {
+			return "", err
+		}
+		var mergeObj string
+		if lets, mergeObj, err = r.processIncludes(scope.Include); err != nil {
+			return "", err
+		}
+		ret = mergeReturn(ret, rel.targetVar, mergeObj)
+		offsetLimit = r.processOffsetLimit(scope.Offset, scope.Limit)
+	}
+
+	if ret == "" {
+		ret = rel.targetVar
+	}
+
+	clauses := []string{fmt.Sprintf("FOR %s IN 1..1 %s %s %s", rel.targetVar, rel.direction.aql(), parentVar, rel.edgeCollection)}
+	clauses = append(clauses, lets...)
+	if where != "" {
+		clauses = append(clauses, "FILTER "+where)
+	}
+	if sortClause != "" {
+		clauses = append(clauses, "SORT "+sortClause)
+	}
+	if offsetLimit != "" {
+		clauses = append(clauses, "LIMIT "+offsetLimit)
+	}
+	clauses = append(clauses, "RETURN "+ret)
+
+	return strings.Join(clauses, " "), nil
+}
+
+// processGroup turns a Group into its COLLECT and AGGREGATE assignment
+// lists. A nil Group yields two empty strings.
+func (r *processRun) processGroup(group *Group) (string, string, error) {
+	if group == nil {
+		return "", "", nil
+	}
+
+	by := make([]string, 0, len(group.By))
+	for _, key := range group.By {
+		name, err := r.validateName(key)
+		if err != nil {
+			return "", "", err
+		}
+		field, err := r.processIdentifier(key)
+		if err != nil {
+			return "", "", err
+		}
+		by = append(by, name+" = "+field)
+	}
+
+	names := make([]string, 0, len(group.Aggregate))
+	for name := range group.Aggregate {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	aggregates := make([]string, 0, len(names))
+	for _, name := range names {
+		spec := group.Aggregate[name]
+		if len(spec) != 1 {
+			return "", "", fmt.Errorf("arangofilters: aggregate %q must have exactly one aggregator", name)
+		}
+
+		resultName, err := r.validateName(name)
+		if err != nil {
+			return "", "", err
+		}
+
+		for aggregator, value := range spec {
+			switch strings.ToLower(aggregator) {
+			case "count":
+				v, ok := value.(bool)
+				if !ok || !v {
+					return "", "", fmt.Errorf(`arangofilters: "count" aggregator value must be true`)
+				}
+				aggregates = append(aggregates, resultName+" = LENGTH(1)")
+			case "sum", "avg", "min", "max":
+				fieldName, ok := value.(string)
+				if !ok {
+					return "", "", fmt.Errorf("arangofilters: %q aggregator value must be a field name", aggregator)
+				}
+				field, err := r.processIdentifier(fieldName)
+				if err != nil {
+					return "", "", err
+				}
+				aggregates = append(aggregates, fmt.Sprintf("%s = %s(%s)", resultName, aggregatorFuncs[strings.ToLower(aggregator)], field))
+			default:
+				return "", "", fmt.Errorf("arangofilters: unknown aggregator %q", aggregator)
+			}
+		}
+	}
+
+	return strings.Join(by, ", "), strings.Join(aggregates, ", "), nil
+}
+
+// validateName validates a bare (unprefixed) AQL variable name, such as a
+// Group.By output key or an Aggregate result name, against the same
+// identifier whitelist used for field paths.
+func (r *processRun) validateName(name string) (string, error) {
+	if !identifierRegexp.MatchString(name) {
+		return "", fmt.Errorf("arangofilters: invalid identifier %q", name)
+	}
+	if reservedWords[strings.ToLower(name)] {
+		return "", fmt.Errorf("arangofilters: %q is a reserved keyword", name)
+	}
+	return name, nil
+}
+
+// processIdentifier validates identifier, which may be a dotted path
+// (optionally indexing into an array, e.g. "tags[0]"), and returns it
+// prefixed with the processor's variable name.
+func (r *processRun) processIdentifier(identifier string) (string, error) {
+	segments, err := r.parseSegments(identifier)
+	if err != nil {
+		return "", err
+	}
+
+	rendered := make([]string, len(segments))
+	for i, s := range segments {
+		rendered[i] = s.render()
+	}
+	return r.varName + "." + strings.Join(rendered, "."), nil
+}
+
+func (r *processRun) processWhere(where []map[string]interface{}) (string, error) {
+	conditions := make([]string, 0, len(where))
+	for _, m := range where {
+		for key, value := range m {
+			cond, err := r.processCondition(key, value)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, cond)
+		}
+	}
+	return strings.Join(conditions, " && "), nil
+}
+
+// processCondition dispatches a single where entry, either a boolean
+// operator ("and"/"or"/"not"), the "like" helper, or a plain field
+// condition.
+func (r *processRun) processCondition(key string, value interface{}) (string, error) {
+	switch strings.ToLower(key) {
+	case "and":
+		return r.processBoolOp(value, "&&")
+	case "or":
+		return r.processBoolOp(value, "||")
+	case "not":
+		return r.processNot(value)
+	case "like":
+		return r.processLike(value)
+	default:
+		return r.processFieldCondition(key, value)
+	}
+}
+
+func (r *processRun) processBoolOp(value interface{}, symbol string) (string, error) {
+	arr, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("arangofilters: %q must be an array of conditions", symbol)
+	}
+
+	conditions := make([]string, 0, len(arr))
+	for _, item := range arr {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("arangofilters: conditions must be objects")
+		}
+
+		for key, val := range m {
+			cond, err := r.processCondition(key, val)
+			if err != nil {
+				return "", err
+			}
+			conditions = append(conditions, cond)
+		}
+	}
+
+	return "(" + strings.Join(conditions, " "+symbol+" ") + ")", nil
+}
+
+func (r *processRun) processNot(value interface{}) (string, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf(`arangofilters: "not" must be an object`)
+	}
+
+	conditions := make([]string, 0, len(m))
+	for key, val := range m {
+		cond, err := r.processCondition(key, val)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, cond)
+	}
+
+	return "!(" + strings.Join(conditions, " && ") + ")", nil
+}
+
+func (r *processRun) processLike(value interface{}) (string, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf(`arangofilters: "like" must be an object`)
+	}
+
+	text, ok := m["text"].(string)
+	if !ok {
+		return "", fmt.Errorf(`arangofilters: "like.text" must be a string`)
+	}
+	search, ok := m["search"].(string)
+	if !ok {
+		return "", fmt.Errorf(`arangofilters: "like.search" must be a string`)
+	}
+
+	field, err := r.processIdentifier(text)
+	if err != nil {
+		return "", err
+	}
+
+	caseInsensitive, _ := m["case_insensitive"].(bool)
+	searchLiteral, err := r.scalarLiteral(search)
+	if err != nil {
+		return "", err
+	}
+
+	if caseInsensitive {
+		return fmt.Sprintf("LIKE(%s, %s, true)", field, searchLiteral), nil
+	}
+	return fmt.Sprintf("LIKE(%s, %s)", field, searchLiteral), nil
+}
+
+func (r *processRun) processFieldCondition(key string, value interface{}) (string, error) {
+	field, err := r.processIdentifier(key)
+	if err != nil {
+		return "", err
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		return r.processOperatorCondition(key, field, m)
+	}
+
+	if arr, ok := value.([]interface{}); ok {
+		rendered, err := r.arrayLiteral(arr)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s IN %s", field, rendered), nil
+	}
+
+	rendered, err := r.scalarLiteral(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s == %s", field, rendered), nil
+}
+
+// processOperatorCondition dispatches the single operator found in m to
+// its AQL rendering. key is the raw (unprefixed) field name, field is its
+// "var.field" form.
+func (r *processRun) processOperatorCondition(key, field string, m map[string]interface{}) (string, error) {
+	for op, value := range m {
+		switch strings.ToLower(op) {
+		case "eq", "neq", "gt", "gte", "lt", "lte":
+			return r.processComparisonOp(field, op, value)
+		case "in", "nin":
+			return r.processInOp(field, op, value)
+		case "between":
+			return r.processBetweenOp(field, value)
+		case "exists":
+			return r.processExistsOp(key, value)
+		case "regex":
+			return r.processRegexOp(field, value)
+		case "size":
+			return r.processSizeOp(field, value)
+		default:
+			return "", fmt.Errorf("arangofilters: unknown operator %q", op)
+		}
+	}
+
+	return "", fmt.Errorf("arangofilters: empty operator condition for %q", field)
+}
+
+func (r *processRun) processComparisonOp(field, op string, value interface{}) (string, error) {
+	rendered, err := r.scalarLiteral(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", field, comparisonOperators[strings.ToLower(op)], rendered), nil
+}
+
+// processInOp renders the "in"/"nin" operators: field IN/NOT IN an array.
+func (r *processRun) processInOp(field, op string, value interface{}) (string, error) {
+	arr, ok := value.([]interface{})
+	if !ok {
+		return "", fmt.Errorf("arangofilters: %q must be an array", op)
+	}
+
+	rendered, err := r.arrayLiteral(arr)
+	if err != nil {
+		return "", err
+	}
+
+	symbol := "IN"
+	if strings.ToLower(op) == "nin" {
+		symbol = "NOT IN"
+	}
+	return fmt.Sprintf("%s %s %s", field, symbol, rendered), nil
+}
+
+// processBetweenOp renders the "between" operator as an inclusive range,
+// since AQL has no native BETWEEN.
+func (r *processRun) processBetweenOp(field string, value interface{}) (string, error) {
+	arr, ok := value.([]interface{})
+	if !ok || len(arr) != 2 {
+		return "", fmt.Errorf(`arangofilters: "between" must be a two-element array`)
+	}
+
+	lower, err := r.scalarLiteral(arr[0])
+	if err != nil {
+		return "", err
+	}
+	upper, err := r.scalarLiteral(arr[1])
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("(%s >= %s && %s <= %s)", field, lower, field, upper), nil
+}
+
+// processExistsOp renders the "exists" operator using AQL's HAS, which
+// takes the parent object and the leaf field name rather than a
+// "var.field" path expression. For a dotted path such as "address.city",
+// the parent is "var.address" and the leaf is "city". HAS has no
+// equivalent for a specific array slot, so a bracketed leaf such as
+// "tags[0]" is rejected rather than silently checked against the wrong
+// thing.
+func (r *processRun) processExistsOp(key string, value interface{}) (string, error) {
+	exists, ok := value.(bool)
+	if !ok {
+		return "", fmt.Errorf(`arangofilters: "exists" must be a boolean`)
+	}
+
+	segments, err := r.parseSegments(key)
+	if err != nil {
+		return "", err
+	}
+
+	leaf := segments[len(segments)-1]
+	if leaf.index != "" {
+		return "", fmt.Errorf("arangofilters: %q: \"exists\" does not support an indexed leaf", key)
+	}
+
+	parent := r.varName
+	for _, s := range segments[:len(segments)-1] {
+		parent += "." + s.render()
+	}
+
+	has := fmt.Sprintf("HAS(%s, \"%s\")", parent, leaf.name)
+	if exists {
+		return has, nil
+	}
+	return "!" + has, nil
+}
+
+// processRegexOp renders the "regex" operator via AQL's REGEX_TEST. value
+// is either the pattern string directly, or a {"pattern", "case_insensitive"}
+// object when case-insensitive matching is needed.
+func (r *processRun) processRegexOp(field string, value interface{}) (string, error) {
+	var pattern string
+	var caseInsensitive bool
+
+	switch v := value.(type) {
+	case string:
+		pattern = v
+	case map[string]interface{}:
+		p, ok := v["pattern"].(string)
+		if !ok {
+			return "", fmt.Errorf(`arangofilters: "regex.pattern" must be a string`)
+		}
+		pattern = p
+		caseInsensitive, _ = v["case_insensitive"].(bool)
+	default:
+		return "", fmt.Errorf("arangofilters: invalid \"regex\" value type %T", value)
+	}
+
+	rendered, err := r.scalarLiteral(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	if caseInsensitive {
+		return fmt.Sprintf("REGEX_TEST(%s, %s, true)", field, rendered), nil
+	}
+	return fmt.Sprintf("REGEX_TEST(%s, %s)", field, rendered), nil
+}
+
+// processSizeOp renders the "size" operator as an AQL LENGTH() equality
+// check.
+func (r *processRun) processSizeOp(field string, value interface{}) (string, error) {
+	n, ok := value.(float64)
+	if !ok {
+		return "", fmt.Errorf(`arangofilters: "size" must be a number`)
+	}
+
+	rendered, err := r.scalarLiteral(n)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("LENGTH(%s) == %s", field, rendered), nil
+}
+
+// scalarLiteral renders a JSON-decoded scalar (string, float64 or bool)
+// as an AQL literal, or as a bind parameter when the processor runs
+// WithBindVars. Any other type, notably plain Go ints, is rejected: the
+// JSON unmarshaller never produces them, so seeing one means the caller
+// built the Filter by hand with the wrong type.
+func (r *processRun) scalarLiteral(value interface{}) (string, error) {
+	if !isScalar(value) {
+		return "", fmt.Errorf("arangofilters: unsupported value type %T", value)
+	}
+	if r.bindVars != nil {
+		return r.bind(value), nil
+	}
+	return literal(value), nil
+}
+
+// arrayLiteral renders an array of scalars (types may be mixed) for use on the
+// right-hand side of an IN condition, as a single AQL array literal, or
+// as a single bind parameter of type slice when the processor runs
+// WithBindVars.
+func (r *processRun) arrayLiteral(arr []interface{}) (string, error) {
+	for _, item := range arr {
+		if !isScalar(item) {
+			return "", fmt.Errorf("arangofilters: unsupported value type %T", item)
+		}
+	}
+
+	if r.bindVars != nil {
+		return r.bind(arr), nil
+	}
+
+	items := make([]string, 0, len(arr))
+	for _, item := range arr {
+		items = append(items, literal(item))
+	}
+	return "[" + strings.Join(items, ", ") + "]", nil
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// literal renders a scalar already validated by isScalar as an AQL
+// literal.
+func literal(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "'" + escapeString(v) + "'"
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// escapeString escapes backslashes and single quotes so a string can be
+// safely embedded in a single-quoted AQL literal.
+func escapeString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return strings.ReplaceAll(s, "'", "\\'")
+}
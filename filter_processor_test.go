@@ -9,6 +9,7 @@ import (
 )
 
 var fp = newFilterProcessor("")
+var fpBind = newFilterProcessor("var", WithBindVars())
 
 var offsetFilter = &Filter{
 	Offset: 1,
@@ -89,6 +90,93 @@ var likeWhereFilter = &Filter{
 	},
 }
 
+var inWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"age": map[string]interface{}{"in": []interface{}{float64(20), float64(25)}}}},
+}
+
+var ninWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"age": map[string]interface{}{"nin": []interface{}{float64(20), float64(25)}}}},
+}
+
+var betweenWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"age": map[string]interface{}{"between": []interface{}{float64(20), float64(30)}}}},
+}
+
+var existsWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"email": map[string]interface{}{"exists": true}}},
+}
+
+var notExistsWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"email": map[string]interface{}{"exists": false}}},
+}
+
+var regexWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"bio": map[string]interface{}{"regex": "^A"}}},
+}
+
+var regexCaseInsensitiveWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"bio": map[string]interface{}{"regex": map[string]interface{}{
+		"pattern":          "^a",
+		"case_insensitive": true,
+	}}}},
+}
+
+var sizeWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"tags": map[string]interface{}{"size": float64(3)}}},
+}
+
+var dottedSortFilter = &Filter{
+	Sort: []string{"address.city ASC", "tags[0] DESC"},
+}
+
+var dottedWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"address.city": "Paris"}},
+}
+
+var dottedExistsWhereFilter = &Filter{
+	Where: []map[string]interface{}{{"address.city": map[string]interface{}{"exists": true}}},
+}
+
+var includeFieldsFilter = &Filter{
+	Fields: map[string]bool{"firstName": true, "age": true},
+}
+
+var excludeFieldsFilter = &Filter{
+	Fields: map[string]bool{"password": false, "ssn": false},
+}
+
+func newFPWithRelations() filterProcessor {
+	p := newFilterProcessor("u")
+	p.RegisterRelation("posts", "userPosts", Outbound, "p")
+	p.RegisterRelation("author", "userPosts", Inbound, "a")
+	return p
+}
+
+var includeFilter = &Filter{
+	Include: []IncludeSpec{{Relation: "posts"}},
+}
+
+var groupFilter = &Filter{
+	Group: &Group{
+		By: []string{"country"},
+		Aggregate: map[string]map[string]interface{}{
+			"total": {"sum": "amount"},
+			"n":     {"count": true},
+		},
+	},
+}
+
+var includeWithScopeFilter = &Filter{
+	Include: []IncludeSpec{{
+		Relation: "posts",
+		Scope: &Filter{
+			Where: []map[string]interface{}{{"published": true}},
+			Sort:  []string{"createdAt DESC"},
+			Limit: 5,
+		},
+	}},
+}
+
 func newAssertRequire(t *testing.T) (*assert.Assertions, *require.Assertions) {
 	a := assert.New(t)
 	r := require.New(t)
@@ -159,6 +247,230 @@ func TestProcessInvalidUsingOperatorSortFilter(t *testing.T) {
 	a.Nil(p)
 }
 
+func TestProcessDottedSortFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(dottedSortFilter)
+	r.NoError(err)
+	a.Equal("var.address.city ASC, var.tags[0] DESC", p.Sort)
+}
+
+func TestProcessDottedWhereFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(dottedWhereFilter)
+	r.NoError(err)
+	a.Equal("var.address.city == 'Paris'", p.Where)
+}
+
+func TestProcessDottedExistsWhereFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(dottedExistsWhereFilter)
+	r.NoError(err)
+	a.Equal(`HAS(var.address, "city")`, p.Where)
+}
+
+func TestProcessInvalidDottedSortFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Sort: []string{"address..city ASC"}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidBracketSortFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Sort: []string{"tags[foo] ASC"}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessIncludeFieldsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(includeFieldsFilter)
+	r.NoError(err)
+	a.Equal("{ age: var.age, firstName: var.firstName }", p.Return)
+}
+
+func TestProcessExcludeFieldsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(excludeFieldsFilter)
+	r.NoError(err)
+	a.Equal(`UNSET(var, "password", "ssn")`, p.Return)
+}
+
+func TestProcessEmptyFieldsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Fields: map[string]bool{}})
+	r.NoError(err)
+	a.Equal("", p.Return)
+}
+
+func TestProcessInvalidMixedFieldsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Fields: map[string]bool{"firstName": true, "password": false}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidFieldsIdentifierFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Fields: map[string]bool{"INSeRT": true}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidDottedFieldsKeyFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Fields: map[string]bool{"address.city": true}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidDottedExcludeFieldsKeyFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Fields: map[string]bool{"address.city": false}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessIncludeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	fpRel := newFPWithRelations()
+	p, err := fpRel.Process(includeFilter)
+	r.NoError(err)
+	r.Len(p.Lets, 1)
+	a.Equal("LET posts = (FOR p IN 1..1 OUTBOUND u userPosts RETURN p)", p.Lets[0])
+	a.Equal("MERGE(u, { posts: posts })", p.Return)
+}
+
+func TestProcessIncludeWithScopeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	fpRel := newFPWithRelations()
+	p, err := fpRel.Process(includeWithScopeFilter)
+	r.NoError(err)
+	r.Len(p.Lets, 1)
+	a.Equal(
+		"LET posts = (FOR p IN 1..1 OUTBOUND u userPosts FILTER p.published == true SORT p.createdAt DESC LIMIT 5 RETURN p)",
+		p.Lets[0],
+	)
+	a.Equal("MERGE(u, { posts: posts })", p.Return)
+}
+
+func TestProcessIncludeInboundFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	fpRel := newFPWithRelations()
+	p, err := fpRel.Process(&Filter{Include: []IncludeSpec{{Relation: "author"}}})
+	r.NoError(err)
+	r.Len(p.Lets, 1)
+	a.Equal("LET author = (FOR a IN 1..1 INBOUND u userPosts RETURN a)", p.Lets[0])
+}
+
+func TestProcessIncludeWithFieldsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	fpRel := newFPWithRelations()
+	p, err := fpRel.Process(&Filter{
+		Fields:  map[string]bool{"firstName": true},
+		Include: []IncludeSpec{{Relation: "posts"}},
+	})
+	r.NoError(err)
+	a.Equal("MERGE({ firstName: u.firstName }, { posts: posts })", p.Return)
+}
+
+func TestProcessInvalidIncludeRelationFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	fpRel := newFPWithRelations()
+	p, err := fpRel.Process(&Filter{Include: []IncludeSpec{{Relation: "unknown"}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidIncludeScopeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	fpRel := newFPWithRelations()
+	p, err := fpRel.Process(&Filter{Include: []IncludeSpec{{
+		Relation: "posts",
+		Scope:    &Filter{Where: []map[string]interface{}{{"age": map[string]interface{}{"eq": 1}}}},
+	}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessGroupFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(groupFilter)
+	r.NoError(err)
+	a.Equal("country = var.country", p.Collect)
+	a.Equal("n = LENGTH(1), total = SUM(var.amount)", p.Aggregate)
+}
+
+func TestProcessGroupMultipleByFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{By: []string{"country", "city"}}})
+	r.NoError(err)
+	a.Equal("country = var.country, city = var.city", p.Collect)
+	a.Equal("", p.Aggregate)
+}
+
+func TestProcessNilGroupFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{})
+	r.NoError(err)
+	a.Equal("", p.Collect)
+	a.Equal("", p.Aggregate)
+}
+
+func TestProcessInvalidGroupByIdentifierFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{By: []string{"INSeRT"}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidGroupByBracketFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{By: []string{"tags[0]"}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidGroupUnknownAggregatorFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{
+		By:        []string{"country"},
+		Aggregate: map[string]map[string]interface{}{"total": {"median": "amount"}},
+	}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidGroupAggregateValueFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{
+		By:        []string{"country"},
+		Aggregate: map[string]map[string]interface{}{"total": {"sum": float64(1)}},
+	}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidGroupMultipleAggregatorsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{
+		By:        []string{"country"},
+		Aggregate: map[string]map[string]interface{}{"total": {"sum": "amount", "avg": "amount"}},
+	}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidGroupCountFalseFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Group: &Group{
+		By:        []string{"country"},
+		Aggregate: map[string]map[string]interface{}{"n": {"count": false}},
+	}})
+	r.Error(err)
+	a.Nil(p)
+}
+
 func TestProcessBasicWhereFilter(t *testing.T) {
 	a, r := newAssertRequire(t)
 	p, err := fp.Process(basicWhereFilter)
@@ -225,6 +537,120 @@ func TestProcessLikeWhereFilter(t *testing.T) {
 	}
 }
 
+func TestProcessInFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(inWhereFilter)
+	r.NoError(err)
+	a.Equal("var.age IN [20, 25]", p.Where)
+}
+
+func TestProcessNinFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(ninWhereFilter)
+	r.NoError(err)
+	a.Equal("var.age NOT IN [20, 25]", p.Where)
+}
+
+func TestProcessBetweenFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(betweenWhereFilter)
+	r.NoError(err)
+	a.Equal("(var.age >= 20 && var.age <= 30)", p.Where)
+}
+
+func TestProcessExistsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(existsWhereFilter)
+	r.NoError(err)
+	a.Equal(`HAS(var, "email")`, p.Where)
+}
+
+func TestProcessNotExistsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(notExistsWhereFilter)
+	r.NoError(err)
+	a.Equal(`!HAS(var, "email")`, p.Where)
+}
+
+func TestProcessInvalidIndexedExistsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{
+		{"tags[0]": map[string]interface{}{"exists": true}},
+	}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessRegexFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(regexWhereFilter)
+	r.NoError(err)
+	a.Equal(`REGEX_TEST(var.bio, '^A')`, p.Where)
+}
+
+func TestProcessRegexCaseInsensitiveFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(regexCaseInsensitiveWhereFilter)
+	r.NoError(err)
+	a.Equal(`REGEX_TEST(var.bio, '^a', true)`, p.Where)
+}
+
+func TestProcessSizeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(sizeWhereFilter)
+	r.NoError(err)
+	a.Equal("LENGTH(var.tags) == 3", p.Where)
+}
+
+func TestProcessInvalidInFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"age": map[string]interface{}{"in": "foo"}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidBetweenLengthFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"age": map[string]interface{}{"between": []interface{}{float64(20)}}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidBetweenTypeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"age": map[string]interface{}{"between": "foo"}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidExistsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"email": map[string]interface{}{"exists": "yes"}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidRegexPatternTypeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"bio": map[string]interface{}{"regex": float64(1)}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidRegexPatternMapFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"bio": map[string]interface{}{"regex": map[string]interface{}{"pattern": float64(1)}}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
+func TestProcessInvalidSizeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"tags": map[string]interface{}{"size": "foo"}}}})
+	r.Error(err)
+	a.Nil(p)
+}
+
 func TestProcessInvalidSimpleConditionTypeFilter(t *testing.T) {
 	a, r := newAssertRequire(t)
 	p, err := fp.Process(&Filter{Where: []map[string]interface{}{{"var.firstName": []interface{}{"foo", map[string]interface{}{"foo": "bar"}}}}})
@@ -320,3 +746,99 @@ func TestEscapeString(t *testing.T) {
 	s := escapeString("O'Hare")
 	a.Equal("O\\'Hare", s)
 }
+
+// Bind vars mode
+
+func TestProcessBindVarsEqFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fpBind.Process(&Filter{Where: []map[string]interface{}{{"password": "qwertyuiop"}}})
+	r.NoError(err)
+	a.Equal("var.password == @p0", p.Where)
+	a.Equal(map[string]interface{}{"p0": "qwertyuiop"}, p.BindVars)
+}
+
+func TestProcessBindVarsOperatorsFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fpBind.Process(&Filter{Where: []map[string]interface{}{
+		{"age": map[string]interface{}{"neq": float64(23)}},
+		{"age": map[string]interface{}{"gt": float64(20)}},
+		{"age": map[string]interface{}{"gte": float64(20)}},
+		{"age": map[string]interface{}{"lt": float64(30)}},
+		{"age": map[string]interface{}{"lte": float64(30)}},
+	}})
+	r.NoError(err)
+	split := strings.Split(p.Where, " && ")
+	a.Equal(5, len(split))
+	expected := []string{
+		"var.age != @p0",
+		"var.age > @p1",
+		"var.age >= @p2",
+		"var.age < @p3",
+		"var.age <= @p4",
+	}
+	for _, s := range split {
+		a.Contains(expected, s)
+	}
+	a.Equal(5, len(p.BindVars))
+}
+
+func TestProcessBindVarsArrayFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fpBind.Process(&Filter{Where: []map[string]interface{}{
+		{"graduated": []interface{}{float64(2010), float64(2015)}},
+	}})
+	r.NoError(err)
+	a.Equal("var.graduated IN @p0", p.Where)
+	a.Equal(map[string]interface{}{"p0": []interface{}{float64(2010), float64(2015)}}, p.BindVars)
+}
+
+func TestProcessBindVarsAndOrNotFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fpBind.Process(&Filter{Where: []map[string]interface{}{
+		{"and": []interface{}{
+			map[string]interface{}{"firstName": map[string]interface{}{"neq": "Toto"}},
+			map[string]interface{}{"money": 200.5},
+		}},
+		{"or": []interface{}{
+			map[string]interface{}{"lastName": "O'Connor"},
+			map[string]interface{}{"age": map[string]interface{}{"gt": float64(23)}},
+		}},
+		{"not": map[string]interface{}{"firstName": "D'Arcy"}},
+	}})
+	r.NoError(err)
+	a.Contains(p.Where, "(var.firstName != @p")
+	a.Contains(p.Where, " && var.money == @p")
+	a.Contains(p.Where, "(var.lastName == @p")
+	a.Contains(p.Where, " || var.age > @p")
+	a.Contains(p.Where, "!(var.firstName == @p")
+	a.Equal(5, len(p.BindVars))
+}
+
+func TestProcessBindVarsLikeFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fpBind.Process(&Filter{Where: []map[string]interface{}{
+		{"like": map[string]interface{}{
+			"text":             "firstName",
+			"search":           "fab%",
+			"case_insensitive": true,
+		}},
+	}})
+	r.NoError(err)
+	a.Equal("LIKE(var.firstName, @p0, true)", p.Where)
+	a.Equal(map[string]interface{}{"p0": "fab%"}, p.BindVars)
+}
+
+func TestProcessBindVarsOffsetLimitFilter(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fpBind.Process(&Filter{Offset: 3, Limit: 4})
+	r.NoError(err)
+	a.Equal("@p0, @p1", p.OffsetLimit)
+	a.Equal(map[string]interface{}{"p0": 3, "p1": 4}, p.BindVars)
+}
+
+func TestProcessNoBindVarsOnLiteralProcessor(t *testing.T) {
+	a, r := newAssertRequire(t)
+	p, err := fp.Process(basicWhereFilter)
+	r.NoError(err)
+	a.Nil(p.BindVars)
+}